@@ -0,0 +1,80 @@
+package node
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Backoff computes retry delays using exponential backoff with full jitter,
+// as described in AWS's "Exponential Backoff and Jitter":
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+//
+// It is safe for concurrent use. The zero value is usable and falls back to
+// a 1s initial delay, a 60s cap, and a 2x multiplier.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+
+	// OnDelay, if set, is called with every delay Next returns, so operators
+	// can export the current backoff as a metric.
+	OnDelay func(time.Duration)
+
+	mu      sync.Mutex
+	attempt int
+}
+
+// Next returns the delay to wait before the next attempt and advances the
+// attempt counter.
+func (b *Backoff) Next() time.Duration {
+	b.mu.Lock()
+	attempt := b.attempt
+	b.attempt++
+	b.mu.Unlock()
+
+	initial := b.initialDelay()
+	max := b.Max
+	if max <= 0 {
+		max = 60 * time.Second
+	}
+	mult := b.Multiplier
+	if mult <= 0 {
+		mult = 2
+	}
+
+	cap := float64(initial) * math.Pow(mult, float64(attempt))
+	if cap > float64(max) {
+		cap = float64(max)
+	}
+
+	delay := time.Duration(rand.Int63n(int64(cap) + 1))
+	if b.OnDelay != nil {
+		b.OnDelay(delay)
+	}
+	return delay
+}
+
+// Reset clears the attempt counter, e.g. after a successful operation, so
+// the next call to Next starts from Initial again.
+func (b *Backoff) Reset() {
+	b.mu.Lock()
+	b.attempt = 0
+	b.mu.Unlock()
+}
+
+// Steady returns the fixed interval to use between successful, healthy
+// operations, as opposed to Next's jittered retry delay. It's Initial (or
+// its default) without any jitter or growth, so a caller ticking at a
+// steady cadence doesn't inherit Next's full-jitter spread.
+func (b *Backoff) Steady() time.Duration {
+	return b.initialDelay()
+}
+
+func (b *Backoff) initialDelay() time.Duration {
+	if b.Initial <= 0 {
+		return time.Second
+	}
+	return b.Initial
+}
@@ -0,0 +1,51 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffNextWithinBounds(t *testing.T) {
+	b := &Backoff{Initial: 10 * time.Millisecond, Max: 100 * time.Millisecond, Multiplier: 2}
+	for i := 0; i < 20; i++ {
+		d := b.Next()
+		if d < 0 || d > b.Max {
+			t.Fatalf("attempt %d: delay %v out of bounds [0, %v]", i, d, b.Max)
+		}
+	}
+}
+
+func TestBackoffResetRestartsFromInitial(t *testing.T) {
+	b := &Backoff{Initial: 10 * time.Millisecond, Max: time.Second, Multiplier: 2}
+	for i := 0; i < 10; i++ {
+		b.Next()
+	}
+	for i := 0; i < 20; i++ {
+		b.Reset()
+		if d := b.Next(); d > b.Initial {
+			t.Fatalf("delay %v exceeds initial cap %v right after Reset", d, b.Initial)
+		}
+	}
+}
+
+func TestBackoffZeroValueUsesDefaults(t *testing.T) {
+	var b Backoff
+	d := b.Next()
+	if d < 0 || d > 60*time.Second {
+		t.Fatalf("zero-value Backoff produced out-of-range delay: %v", d)
+	}
+}
+
+func TestBackoffSteadyIsFixedNotJittered(t *testing.T) {
+	b := &Backoff{Initial: 42 * time.Millisecond, Max: time.Second, Multiplier: 2}
+	for i := 0; i < 5; i++ {
+		if d := b.Steady(); d != b.Initial {
+			t.Fatalf("Steady() = %v, want fixed %v", d, b.Initial)
+		}
+	}
+
+	var zero Backoff
+	if d := zero.Steady(); d != time.Second {
+		t.Fatalf("zero-value Steady() = %v, want default 1s", d)
+	}
+}
@@ -0,0 +1,38 @@
+package node
+
+import "encoding/json"
+
+// Codec encodes and decodes the frames exchanged over a node's websocket
+// connection. It is negotiated once, during HTTP registration, by sending
+// Name() as the "encoding" field; the same codec is then used for every
+// frame in both directions. JSON is the default; plug in your own (e.g.
+// protobuf) by implementing Codec and passing it to WithEncoding.
+type Codec interface {
+	// Name identifies the codec to the server during registration, e.g.
+	// "json", "msgpack", "cbor".
+	Name() string
+	// Binary reports whether frames should be sent as websocket binary
+	// messages rather than text ones.
+	Binary() bool
+	// Marshal encodes v, always a map[string]any, into a wire frame.
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes a wire frame into v, a pointer to a map[string]any.
+	Unmarshal(data []byte, v any) error
+}
+
+// JSON is the default wire codec: human-readable text frames.
+var JSON Codec = jsonCodec{}
+
+// MsgPack encodes frames with MessagePack, useful for media-heavy nodes that
+// would otherwise have to base64 blobs inside JSON.
+var MsgPack Codec = msgpackCodec{}
+
+// CBOR encodes frames with CBOR, an alternative binary codec to MsgPack.
+var CBOR Codec = cborCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                       { return "json" }
+func (jsonCodec) Binary() bool                       { return false }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
@@ -0,0 +1,20 @@
+package node
+
+import (
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborDecMode decodes nested maps as map[string]any, like the other codecs,
+// so a job's "inputs" map survives decoding.
+var cborDecMode, _ = cbor.DecOptions{
+	DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+}.DecMode()
+
+type cborCodec struct{}
+
+func (cborCodec) Name() string                       { return "cbor" }
+func (cborCodec) Binary() bool                       { return true }
+func (cborCodec) Marshal(v any) ([]byte, error)      { return cbor.Marshal(v) }
+func (cborCodec) Unmarshal(data []byte, v any) error { return cborDecMode.Unmarshal(data, v) }
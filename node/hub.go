@@ -0,0 +1,205 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const sendQueueSize = 32
+
+// hub multiplexes every node registered on a Node value over a single
+// Transport connection: inbound frames are dispatched to the handler named
+// by their "node" field on a bounded worker pool, and every outbound write
+// funnels through one writer goroutine so the read loop never races with it
+// over the transport.
+type hub struct {
+	node      *Node
+	transport Transport
+	pool      *pool
+
+	send      chan []byte
+	closeConn chan struct{}
+	closeOnce sync.Once
+}
+
+func newHub(n *Node, t Transport) *hub {
+	return &hub{
+		node:      n,
+		transport: t,
+		pool:      newPool(n.workerPoolSize),
+		send:      make(chan []byte, sendQueueSize),
+		closeConn: make(chan struct{}),
+	}
+}
+
+// run serves the transport until ctx is canceled or it fails. On the way out
+// it drains any jobs still in flight, so their responses have a chance to
+// reach the writer before the transport is closed.
+func (h *hub) run(ctx context.Context) {
+	go h.writePump()
+
+	h.readPump(ctx)
+
+	h.pool.drain()
+	h.requestClose()
+}
+
+func (h *hub) requestClose() {
+	h.closeOnce.Do(func() {
+		close(h.closeConn)
+		_ = h.transport.Close()
+	})
+}
+
+func (h *hub) writePump() {
+	for {
+		select {
+		case <-h.closeConn:
+			return
+		case msg, ok := <-h.send:
+			if !ok {
+				return
+			}
+			if err := h.transport.Write(msg); err != nil {
+				h.node.logger.Warn("transport write failed", "err", err)
+				h.requestClose()
+				return
+			}
+		}
+	}
+}
+
+func (h *hub) readPump(ctx context.Context) {
+	for {
+		msg, err := h.transport.Read()
+		if err != nil {
+			if ctx.Err() == nil {
+				h.node.logger.Warn("transport read failed", "err", err)
+			}
+			return
+		}
+		h.node.dialBackoff.Reset()
+
+		var payload map[string]any
+		if err := h.node.activeCodec.Get().Unmarshal(msg, &payload); err != nil {
+			// ignore unparseable frames
+			continue
+		}
+
+		h.pool.submit(func() { h.handleJob(ctx, payload) })
+	}
+}
+
+// handleJob runs the handler registered for the job's node, honoring an
+// optional per-request timeout_ms deadline, and enqueues its outputs (as one
+// or more frames, for a streaming handler) or a structured error frame.
+func (h *hub) handleJob(ctx context.Context, payload map[string]any) {
+	name, _ := payload["node"].(string)
+	uuid, _ := payload["uuid"].(string)
+
+	reg := h.node.registration(name)
+	if reg == nil {
+		// not one of ours on this connection
+		return
+	}
+
+	// JSON decodes numbers to float64; the binary codecs decode integers to
+	// sized int/uint types.
+	var ms float64
+	switch v := reflect.ValueOf(payload["timeout_ms"]); v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		ms = v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ms = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ms = float64(v.Uint())
+	}
+
+	jobCtx := ctx
+	if ms > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, time.Duration(ms)*time.Millisecond)
+		defer cancel()
+	}
+
+	var inputs map[string]any
+	if m, ok := payload["inputs"].(map[string]any); ok {
+		inputs = m
+	}
+	req := Request{UUID: uuid, Inputs: inputs}
+
+	var err error
+	if reg.streamHandler != nil {
+		w := &ResponseWriter{name: name, uuid: uuid, hub: h}
+		err = reg.streamHandler(jobCtx, req, w)
+		if err == nil {
+			w.Final(nil)
+		}
+	} else {
+		var outputs Outputs
+		outputs, err = reg.handler(jobCtx, req)
+		if err == nil {
+			h.enqueue(h.doneFrame(name, uuid, outputs))
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	code := "handler_error"
+	if errors.Is(jobCtx.Err(), context.DeadlineExceeded) {
+		code = "timeout"
+		err = jobCtx.Err()
+	} else if jobCtx.Err() != nil {
+		err = jobCtx.Err()
+	}
+	h.node.logger.Warn("job failed", "node", name, "uuid", uuid, "err", err)
+	h.enqueue(h.errorFrame(name, uuid, code, err.Error()))
+}
+
+func (h *hub) doneFrame(name, uuid string, outputs Outputs) []byte {
+	out, _ := h.node.activeCodec.Get().Marshal(map[string]any{
+		"node":    name,
+		"uuid":    uuid,
+		"done":    true,
+		"outputs": outputs,
+	})
+	return out
+}
+
+func (h *hub) partialFrame(name, uuid string, outputs Outputs) []byte {
+	out, _ := h.node.activeCodec.Get().Marshal(map[string]any{
+		"node":    name,
+		"uuid":    uuid,
+		"partial": true,
+		"outputs": outputs,
+	})
+	return out
+}
+
+func (h *hub) errorFrame(name, uuid, code, message string) []byte {
+	out, _ := h.node.activeCodec.Get().Marshal(map[string]any{
+		"node": name,
+		"uuid": uuid,
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+	return out
+}
+
+// enqueue queues msg for the writer goroutine. A consumer that can't keep up
+// with its own outbound traffic is disconnected rather than allowed to block
+// the read loop indefinitely.
+func (h *hub) enqueue(msg []byte) {
+	select {
+	case h.send <- msg:
+	default:
+		h.node.logger.Warn("send queue full, disconnecting slow consumer")
+		h.requestClose()
+	}
+}
@@ -0,0 +1,166 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errBoom = errors.New("boom")
+
+// fakeTransport is an in-memory Transport for exercising the hub without a
+// real network connection.
+type fakeTransport struct {
+	in     chan []byte
+	out    chan []byte
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{
+		in:     make(chan []byte, 8),
+		out:    make(chan []byte, 8),
+		closed: make(chan struct{}),
+	}
+}
+
+func (t *fakeTransport) Dial(ctx context.Context, info wsInfo) error { return nil }
+
+func (t *fakeTransport) Read() ([]byte, error) {
+	select {
+	case msg := <-t.in:
+		return msg, nil
+	case <-t.closed:
+		return nil, io.EOF
+	}
+}
+
+func (t *fakeTransport) Write(msg []byte) error {
+	select {
+	case t.out <- msg:
+		return nil
+	case <-t.closed:
+		return io.ErrClosedPipe
+	}
+}
+
+func (t *fakeTransport) Close() error {
+	t.once.Do(func() { close(t.closed) })
+	return nil
+}
+
+func (t *fakeTransport) recv(t2 *testing.T) map[string]any {
+	t2.Helper()
+	select {
+	case out := <-t.out:
+		var got map[string]any
+		if err := JSON.Unmarshal(out, &got); err != nil {
+			t2.Fatalf("unmarshal output frame: %v", err)
+		}
+		return got
+	case <-time.After(time.Second):
+		t2.Fatal("timed out waiting for output frame")
+		return nil
+	}
+}
+
+func TestHubDispatchesJobToRegisteredHandler(t *testing.T) {
+	n := New("http://example.invalid", "token")
+	n.Register("echo", func(ctx context.Context, req Request) (Outputs, error) {
+		return Outputs{"echoed": req.Inputs["msg"]}, nil
+	})
+
+	transport := newFakeTransport()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		newHub(n, transport).run(ctx)
+		close(done)
+	}()
+
+	frame, _ := JSON.Marshal(map[string]any{
+		"node":   "echo",
+		"uuid":   "job-1",
+		"inputs": map[string]any{"msg": "hi"},
+	})
+	transport.in <- frame
+
+	got := transport.recv(t)
+	if got["uuid"] != "job-1" {
+		t.Errorf("uuid = %v, want job-1", got["uuid"])
+	}
+	outputs, _ := got["outputs"].(map[string]any)
+	if outputs["echoed"] != "hi" {
+		t.Errorf("echoed = %v, want hi", outputs["echoed"])
+	}
+
+	transport.Close()
+	cancel()
+	<-done
+}
+
+func TestHubIgnoresJobForUnregisteredNode(t *testing.T) {
+	n := New("http://example.invalid", "token")
+
+	transport := newFakeTransport()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		newHub(n, transport).run(ctx)
+		close(done)
+	}()
+
+	frame, _ := JSON.Marshal(map[string]any{
+		"node": "nonexistent",
+		"uuid": "job-2",
+	})
+	transport.in <- frame
+
+	select {
+	case out := <-transport.out:
+		t.Fatalf("expected no response for an unregistered node, got %s", out)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	transport.Close()
+	cancel()
+	<-done
+}
+
+func TestHubReportsHandlerError(t *testing.T) {
+	n := New("http://example.invalid", "token")
+	n.Register("fail", func(ctx context.Context, req Request) (Outputs, error) {
+		return nil, errBoom
+	})
+
+	transport := newFakeTransport()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		newHub(n, transport).run(ctx)
+		close(done)
+	}()
+
+	frame, _ := JSON.Marshal(map[string]any{"node": "fail", "uuid": "job-3"})
+	transport.in <- frame
+
+	got := transport.recv(t)
+	errField, _ := got["error"].(map[string]any)
+	if errField["code"] != "handler_error" {
+		t.Errorf("error.code = %v, want handler_error", errField["code"])
+	}
+
+	transport.Close()
+	cancel()
+	<-done
+}
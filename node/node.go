@@ -0,0 +1,397 @@
+// Package node provides a reusable client for building Hurozo remote nodes.
+//
+// A Node registers itself with a Hurozo instance over HTTP and then serves
+// requests over a pluggable Transport (a websocket connection by default),
+// so callers only have to supply the business logic for their handlers:
+// dialing, re-registration, keepalive, and frame encoding are all owned by
+// the package. A single Node can host several named handlers, all
+// multiplexed over one connection by an internal hub.
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Request is the decoded inbound job passed to a HandlerFunc.
+type Request struct {
+	UUID   string
+	Inputs map[string]any
+}
+
+// Blob returns the named input as raw bytes, for binary-codec nodes (see
+// WithEncoding) that pass media blobs as native []byte values instead of
+// base64-encoding them. It reports false if key is absent or not a []byte.
+func (r Request) Blob(key string) ([]byte, bool) {
+	b, ok := r.Inputs[key].([]byte)
+	return b, ok
+}
+
+// Outputs is the set of named results a HandlerFunc returns to the caller.
+type Outputs map[string]any
+
+// HandlerFunc implements the business logic for a registered node.
+type HandlerFunc func(ctx context.Context, req Request) (Outputs, error)
+
+// Option configures optional metadata passed to Register.
+type Option func(*registration)
+
+// WithInputs sets the named inputs a node accepts. It is purely descriptive:
+// it is reported to the server on registration and is not validated here.
+func WithInputs(inputs ...string) Option {
+	return func(r *registration) { r.inputs = inputs }
+}
+
+// WithOutputs sets the named outputs a node produces.
+func WithOutputs(outputs ...string) Option {
+	return func(r *registration) { r.outputs = outputs }
+}
+
+// NodeOption configures a Node at construction time.
+type NodeOption func(*Node)
+
+// WithWorkerPoolSize bounds how many jobs a Node processes concurrently per
+// connection. It defaults to defaultWorkerPoolSize.
+func WithWorkerPoolSize(size int) NodeOption {
+	return func(n *Node) { n.workerPoolSize = size }
+}
+
+// WithEncoding negotiates a non-default wire codec (MsgPack, CBOR, or a
+// custom one) for websocket frames. The default is JSON.
+func WithEncoding(codec Codec) NodeOption {
+	return func(n *Node) { n.codec = codec }
+}
+
+// WithLogger routes the node's internal diagnostics through l instead of
+// slog.Default().
+func WithLogger(l *slog.Logger) NodeOption {
+	return func(n *Node) { n.logger = l }
+}
+
+// WithRegisterBackoff overrides the backoff applied between registration
+// attempts. A failed attempt waits Next's jittered delay before retrying;
+// once registration succeeds, it resets and subsequent re-registrations
+// wait Steady's fixed interval instead, so the default (a 60s initial
+// delay) behaves like the old fixed 60s registration interval once the
+// node is healthy, rather than a jittered one.
+func WithRegisterBackoff(b *Backoff) NodeOption {
+	return func(n *Node) { n.registerBackoff = b }
+}
+
+// WithDialBackoff overrides the backoff applied between transport dial
+// attempts. It resets whenever a frame is read successfully.
+func WithDialBackoff(b *Backoff) NodeOption {
+	return func(n *Node) { n.dialBackoff = b }
+}
+
+// registration holds what Register or RegisterStream collected for one
+// named node. Exactly one of handler or streamHandler is set.
+type registration struct {
+	name    string
+	inputs  []string
+	outputs []string
+
+	handler       HandlerFunc
+	streamHandler StreamHandlerFunc
+}
+
+// Node registers one or more named remote nodes with a Hurozo instance and
+// serves all of their requests over a single shared transport connection.
+type Node struct {
+	baseURL  string
+	apiToken string
+
+	mu            sync.RWMutex
+	registrations map[string]*registration
+
+	workerPoolSize  int
+	codec           Codec
+	registerBackoff *Backoff
+	dialBackoff     *Backoff
+	logger          *slog.Logger
+
+	httpClient  *http.Client
+	shared      wsInfoSafe
+	activeCodec codecSafe
+}
+
+// New creates a Node that registers against the given Hurozo base URL using
+// apiToken for authentication.
+func New(baseURL, apiToken string, opts ...NodeOption) *Node {
+	n := &Node{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		apiToken: apiToken,
+		codec:    JSON,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+		registerBackoff: &Backoff{Initial: 60 * time.Second, Max: 5 * time.Minute, Multiplier: 2},
+		dialBackoff:     &Backoff{Initial: time.Second, Max: 30 * time.Second, Multiplier: 2},
+		logger:          slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	n.activeCodec.Set(n.codec)
+	return n
+}
+
+// Register attaches the business logic handler for name. It may be called
+// more than once to host several nodes on the same connection, and must be
+// called before Run.
+func (n *Node) Register(name string, handler HandlerFunc, opts ...Option) {
+	reg := &registration{name: name, handler: handler}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.registrations == nil {
+		n.registrations = make(map[string]*registration)
+	}
+	n.registrations[name] = reg
+}
+
+// RegisterStream attaches a streaming handler for name, letting it emit
+// interim results via the ResponseWriter passed to it before sending a
+// final one. It may be called more than once to host several nodes on the
+// same connection, and must be called before Run.
+func (n *Node) RegisterStream(name string, handler StreamHandlerFunc, opts ...Option) {
+	reg := &registration{name: name, streamHandler: handler}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.registrations == nil {
+		n.registrations = make(map[string]*registration)
+	}
+	n.registrations[name] = reg
+}
+
+func (n *Node) registration(name string) *registration {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.registrations[name]
+}
+
+// Run registers the node with the server and serves requests until ctx is
+// canceled.
+func (n *Node) Run(ctx context.Context) {
+	go n.registerLoop(ctx)
+	n.connectLoop(ctx)
+}
+
+// wsInfo is the registration response: where to dial, how to authenticate,
+// which transports the server is willing to serve (in preference order),
+// and which wire encoding it agreed to use.
+type wsInfo struct {
+	URL        string   `json:"websocket_url"`
+	Token      string   `json:"token"`
+	Transports []string `json:"transports"`
+	Encoding   string   `json:"encoding"`
+}
+
+type wsInfoSafe struct {
+	mu sync.RWMutex
+	v  wsInfo
+}
+
+func (s *wsInfoSafe) Get() wsInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.v
+}
+
+func (s *wsInfoSafe) Set(v wsInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.v = v
+}
+
+// codecSafe holds the codec actually in use for the current connection. It
+// starts out as the configured codec (see WithEncoding) but connectLoop may
+// downgrade it to JSON per connection if the server didn't accept it.
+type codecSafe struct {
+	mu sync.RWMutex
+	v  Codec
+}
+
+func (s *codecSafe) Get() Codec {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.v
+}
+
+func (s *codecSafe) Set(v Codec) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.v = v
+}
+
+// negotiatedCodec resolves the codec a connection should use: the one
+// requested during registration (n.codec), or JSON if the registration
+// response shows the server didn't agree to it. An empty info.Encoding means
+// the server predates encoding negotiation, so the request is trusted as-is.
+func (n *Node) negotiatedCodec(info wsInfo) Codec {
+	if info.Encoding == "" || info.Encoding == n.codec.Name() {
+		return n.codec
+	}
+	n.logger.Warn("server did not accept requested encoding, falling back to JSON",
+		"requested", n.codec.Name(), "accepted", info.Encoding)
+	return JSON
+}
+
+func (n *Node) registerLoop(ctx context.Context) {
+	type nodeMeta struct {
+		Name    string   `json:"name"`
+		Inputs  []string `json:"inputs"`
+		Outputs []string `json:"outputs"`
+	}
+	type registerReq struct {
+		Nodes    []nodeMeta `json:"nodes"`
+		Encoding string     `json:"encoding"`
+	}
+
+	endpoint := fmt.Sprintf("%s/api/remote_nodes/register", n.baseURL)
+
+	for {
+		n.mu.RLock()
+		nodes := make([]nodeMeta, 0, len(n.registrations))
+		for _, reg := range n.registrations {
+			nodes = append(nodes, nodeMeta{Name: reg.name, Inputs: reg.inputs, Outputs: reg.outputs})
+		}
+		n.mu.RUnlock()
+
+		buf, _ := json.Marshal(registerReq{Nodes: nodes, Encoding: n.codec.Name()})
+
+		registered := false
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(buf))
+		if err == nil {
+			req.Header.Set("Authorization", "Bearer "+n.apiToken)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := n.httpClient.Do(req)
+			if err == nil {
+				func() {
+					defer resp.Body.Close()
+					b, _ := io.ReadAll(resp.Body)
+
+					if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+						var data wsInfo
+						if err := json.Unmarshal(b, &data); err == nil && data.URL != "" && data.Token != "" {
+							n.shared.Set(data)
+							n.registerBackoff.Reset()
+							registered = true
+						}
+					} else {
+						n.logger.Warn("registration failed", "status", resp.Status, "body", string(b))
+					}
+				}()
+			} else {
+				n.logger.Error("registration request failed", "err", err)
+			}
+		} else {
+			n.logger.Error("registration request build failed", "err", err)
+		}
+
+		// A healthy node re-registers on a steady heartbeat; only a failed
+		// attempt backs off with jitter, so registerBackoff's Next isn't
+		// doubling as the steady-state interval.
+		delay := n.registerBackoff.Steady()
+		if !registered {
+			delay = n.registerBackoff.Next()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// maxTransportDialFailures is how many times in a row a transport may fail
+// to dial before connectLoop moves on to the next one the server offered.
+const maxTransportDialFailures = 3
+
+// connectLoop dials a transport and serves it until the connection drops,
+// repeating for as long as ctx is live. It picks from the transports the
+// server advertised at registration (falling back to defaultTransportOrder),
+// and demotes a transport that keeps failing to dial in favor of the next
+// one in the list, the way engine.io upgrades/downgrades between websocket
+// and long-polling.
+func (n *Node) connectLoop(ctx context.Context) {
+	failures := map[string]int{}
+	idx := 0
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		info := n.shared.Get()
+		if info.URL == "" || info.Token == "" {
+			time.Sleep(1 * time.Second)
+			continue
+		}
+
+		order := supportedTransports(info.Transports)
+		if len(order) == 0 {
+			order = defaultTransportOrder
+		}
+		name := order[idx%len(order)]
+
+		codec := n.negotiatedCodec(info)
+		if name == "longpoll" && codec.Binary() {
+			// The poll/reply envelope is plain JSON; there's no framing yet
+			// for embedding a binary codec's bytes inside it, so long-poll
+			// always speaks JSON regardless of what was negotiated for the
+			// websocket transport.
+			n.logger.Warn("long-poll transport doesn't support binary codecs, falling back to JSON", "codec", codec.Name())
+			codec = JSON
+		}
+		n.activeCodec.Set(codec)
+
+		t := transportFactories[name](n)
+		if err := t.Dial(ctx, info); err != nil {
+			failures[name]++
+			n.logger.Warn("transport dial failed", "transport", name, "attempt", failures[name], "err", err)
+			if failures[name] >= maxTransportDialFailures && len(order) > 1 {
+				idx++
+				failures[name] = 0
+			}
+			if !n.sleep(ctx, n.dialBackoff.Next()) {
+				return
+			}
+			continue
+		}
+		failures[name] = 0
+
+		newHub(n, t).run(ctx)
+		if !n.sleep(ctx, n.dialBackoff.Next()) {
+			return
+		}
+	}
+}
+
+// sleep waits for d or ctx's cancellation, whichever comes first. It reports
+// whether the wait completed normally.
+func (n *Node) sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
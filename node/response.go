@@ -0,0 +1,38 @@
+package node
+
+import "context"
+
+// StreamHandlerFunc implements the business logic for a registered node that
+// emits one or more output frames per job, e.g. token-by-token LLM output or
+// progress updates for a long-running task.
+type StreamHandlerFunc func(ctx context.Context, req Request, w *ResponseWriter) error
+
+// ResponseWriter lets a StreamHandlerFunc emit interim and final output
+// frames for a single job. Partial may be called any number of times before
+// Final; once Final has been called (or the handler returns nil, which implies
+// a Final with no outputs), further writes are no-ops. A handler that returns
+// an error gets an error frame instead of an implied Final.
+type ResponseWriter struct {
+	name string
+	uuid string
+	hub  *hub
+	done bool
+}
+
+// Partial sends an interim result for the job. It does not end the job.
+func (w *ResponseWriter) Partial(outputs Outputs) {
+	if w.done {
+		return
+	}
+	w.hub.enqueue(w.hub.partialFrame(w.name, w.uuid, outputs))
+}
+
+// Final sends the job's last result and ends it. Subsequent calls to Partial
+// or Final are ignored.
+func (w *ResponseWriter) Final(outputs Outputs) {
+	if w.done {
+		return
+	}
+	w.done = true
+	w.hub.enqueue(w.hub.doneFrame(w.name, w.uuid, outputs))
+}
@@ -0,0 +1,39 @@
+package node
+
+import "context"
+
+// Transport abstracts how frames travel between a Node and the server, so
+// alternatives to a raw websocket connection can be swapped in without
+// touching the hub's dispatch logic. Dial receives the connection info
+// learned during HTTP registration; Read and Write exchange one already
+// codec-encoded frame at a time.
+type Transport interface {
+	Dial(ctx context.Context, info wsInfo) error
+	Read() ([]byte, error)
+	Write(msg []byte) error
+	Close() error
+}
+
+// transportFactory builds a fresh, undialed Transport for a Node.
+type transportFactory func(n *Node) Transport
+
+var transportFactories = map[string]transportFactory{
+	"websocket": newWebsocketTransport,
+	"longpoll":  newLongPollTransport,
+}
+
+// defaultTransportOrder is tried when the server's registration response
+// doesn't include a "transports" preference of its own.
+var defaultTransportOrder = []string{"websocket", "longpoll"}
+
+// supportedTransports filters names down to the ones this package knows how
+// to dial, preserving the server's preferred order.
+func supportedTransports(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := transportFactories[name]; ok {
+			out = append(out, name)
+		}
+	}
+	return out
+}
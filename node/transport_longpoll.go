@@ -0,0 +1,164 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// longPollInterval is how long pollLoop waits after each poll, whether or not
+// it returned frames, before issuing the next one.
+const longPollInterval = 2 * time.Second
+
+// longPollTransport is a Transport fallback for environments where outbound
+// websockets are blocked, e.g. by a corporate proxy. It polls
+// /api/remote_nodes/poll for inbound frames and posts outbound ones to
+// /api/remote_nodes/reply.
+type longPollTransport struct {
+	baseURL    string
+	apiToken   string
+	httpClient *http.Client
+	logger     *slog.Logger
+	codec      Codec
+
+	session string // the token from registration, used to correlate polls
+
+	done      chan struct{}
+	closeOnce sync.Once
+	inbox     chan []byte
+}
+
+func newLongPollTransport(n *Node) Transport {
+	return &longPollTransport{
+		baseURL:    n.baseURL,
+		apiToken:   n.apiToken,
+		httpClient: n.httpClient,
+		logger:     n.logger,
+		codec:      n.activeCodec.Get(),
+		done:       make(chan struct{}),
+		inbox:      make(chan []byte, 32),
+	}
+}
+
+func (t *longPollTransport) Dial(ctx context.Context, info wsInfo) error {
+	t.session = info.Token
+	go t.pollLoop(ctx)
+	return nil
+}
+
+func (t *longPollTransport) pollLoop(ctx context.Context) {
+	endpoint := fmt.Sprintf("%s/api/remote_nodes/poll", t.baseURL)
+
+	for {
+		select {
+		case <-ctx.Done():
+			close(t.inbox)
+			return
+		case <-t.done:
+			close(t.inbox)
+			return
+		default:
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err == nil {
+			req.Header.Set("Authorization", "Bearer "+t.apiToken)
+			q := req.URL.Query()
+			q.Set("session", t.session)
+			req.URL.RawQuery = q.Encode()
+
+			resp, err := t.httpClient.Do(req)
+			if err == nil {
+				for _, f := range t.readFrames(resp) {
+					// A reader that stopped draining inbox (transport closed,
+					// or ctx canceled) must not leak this goroutine forever.
+					select {
+					case t.inbox <- f:
+					case <-ctx.Done():
+						close(t.inbox)
+						return
+					case <-t.done:
+						close(t.inbox)
+						return
+					}
+				}
+			} else {
+				t.logger.Warn("long-poll request failed", "err", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-t.done:
+		case <-time.After(longPollInterval):
+		}
+	}
+}
+
+// readFrames extracts the individual frames from a poll response body,
+// closing it in the process. It returns nil on any malformed or empty
+// response.
+func (t *longPollTransport) readFrames(resp *http.Response) [][]byte {
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || len(b) == 0 {
+		return nil
+	}
+	var raw []json.RawMessage
+	if json.Unmarshal(b, &raw) != nil {
+		return nil
+	}
+	frames := make([][]byte, len(raw))
+	for i, f := range raw {
+		frames[i] = []byte(f)
+	}
+	return frames
+}
+
+func (t *longPollTransport) Read() ([]byte, error) {
+	msg, ok := <-t.inbox
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func (t *longPollTransport) Write(msg []byte) error {
+	endpoint := fmt.Sprintf("%s/api/remote_nodes/reply", t.baseURL)
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiToken)
+	contentType := "application/json"
+	if t.codec.Binary() {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	q := req.URL.Query()
+	q.Set("session", t.session)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("long-poll reply failed: %s", resp.Status)
+	}
+	return nil
+}
+
+func (t *longPollTransport) Close() error {
+	t.closeOnce.Do(func() { close(t.done) })
+	return nil
+}
@@ -0,0 +1,110 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	handshakeTimeout = 30 * time.Second
+	writeWait        = 10 * time.Second
+	pingPeriod       = 30 * time.Second
+	pongWait         = 120 * time.Second
+)
+
+// websocketTransport is the default, low-latency Transport: a long-lived
+// websocket connection with its own ping/pong keepalive.
+type websocketTransport struct {
+	msgType int
+
+	conn      *websocket.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newWebsocketTransport(n *Node) Transport {
+	msgType := websocket.TextMessage
+	if n.activeCodec.Get().Binary() {
+		msgType = websocket.BinaryMessage
+	}
+	return &websocketTransport{msgType: msgType}
+}
+
+func (t *websocketTransport) Dial(ctx context.Context, info wsInfo) error {
+	u, err := url.Parse(info.URL)
+	if err != nil {
+		return fmt.Errorf("invalid websocket_url: %w", err)
+	}
+	q := u.Query()
+	q.Set("auth", info.Token)
+	u.RawQuery = q.Encode()
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout:  handshakeTimeout,
+		EnableCompression: true,
+	}
+	conn, _, err := dialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	t.conn = conn
+	t.done = make(chan struct{})
+
+	// Force the blocking read in Read() to return once ctx is canceled, so
+	// shutdown doesn't have to wait for the next inbound frame.
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetReadDeadline(time.Now())
+		case <-t.done:
+		}
+	}()
+
+	go t.pingLoop()
+	return nil
+}
+
+func (t *websocketTransport) pingLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			if err := t.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (t *websocketTransport) Read() ([]byte, error) {
+	_, msg, err := t.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	_ = t.conn.SetReadDeadline(time.Now().Add(pongWait))
+	return msg, nil
+}
+
+func (t *websocketTransport) Write(msg []byte) error {
+	_ = t.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return t.conn.WriteMessage(t.msgType, msg)
+}
+
+func (t *websocketTransport) Close() error {
+	t.closeOnce.Do(func() { close(t.done) })
+	return t.conn.Close()
+}
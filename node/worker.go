@@ -0,0 +1,38 @@
+package node
+
+import "sync"
+
+// defaultWorkerPoolSize bounds job concurrency per connection when no
+// explicit size is configured via WithWorkerPoolSize.
+const defaultWorkerPoolSize = 16
+
+// pool bounds how many jobs run concurrently for one hub connection and
+// lets the hub wait for in-flight jobs to finish during a graceful shutdown.
+type pool struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+func newPool(size int) *pool {
+	if size <= 0 {
+		size = defaultWorkerPoolSize
+	}
+	return &pool{sem: make(chan struct{}, size)}
+}
+
+// submit blocks until a worker slot is free, then runs fn in its own
+// goroutine.
+func (p *pool) submit(fn func()) {
+	p.sem <- struct{}{}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.sem }()
+		fn()
+	}()
+}
+
+// drain blocks until every submitted job has finished.
+func (p *pool) drain() {
+	p.wg.Wait()
+}